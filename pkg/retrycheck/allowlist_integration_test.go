@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package retrycheck
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// TestAnalyzerAllowlist exercises the .retrycheck.yaml allowlist end to
+// end: a rule that matches the TestAllowlistedFatal diagnostic suppresses
+// it, and a rule that never matches anything is reported as stale.
+// loadAllowlist reads the process's working directory, so the test chdirs
+// into the testdata package itself, which is where its .retrycheck.yaml
+// lives, and resets the cached allowlist around the run so it doesn't leak
+// into the other tests in this package that share the same process.
+func TestAnalyzerAllowlist(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(filepath.Join(testdata, "src", "allowlisted")); err != nil {
+		t.Fatalf("failed to chdir into testdata package: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	resetAllowlistCache()
+	defer resetAllowlistCache()
+
+	analysistest.Run(t, testdata, Analyzer, "allowlisted")
+}
+
+// resetAllowlistCache clears loadAllowlist's process-wide cache so a test
+// that chdirs to a directory with its own .retrycheck.yaml doesn't read an
+// allowlist cached from another test's working directory, and doesn't
+// leave its own allowlist cached for tests that run after it.
+func resetAllowlistCache() {
+	allowlistOnce = sync.Once{}
+	allowlist = nil
+	allowlistErr = nil
+}
@@ -0,0 +1,164 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package retrycheck
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// testingTBMethods are the testing.TB methods that must not be called on
+// the outer test handle from inside a retry.Run/RunWith closure: the
+// obvious failure methods, plus Skip/SkipNow (abort the retry early the
+// same way Fatal does), Cleanup (registers against the outer test, not the
+// retry attempt), and TempDir/Setenv/Deadline/Chdir (mutate or read state
+// scoped to the outer test and are meant to run once, not once per retry).
+var testingTBMethods = map[string]bool{
+	"Error":    true,
+	"Errorf":   true,
+	"Fail":     true,
+	"FailNow":  true,
+	"Fatal":    true,
+	"Fatalf":   true,
+	"Skip":     true,
+	"Skipf":    true,
+	"SkipNow":  true,
+	"Cleanup":  true,
+	"TempDir":  true,
+	"Setenv":   true,
+	"Deadline": true,
+	"Chdir":    true,
+}
+
+// fixableTestingTBMethods are the testingTBMethods that *retry.R has a
+// direct per-attempt equivalent of, and so are safe for -fix to rewrite a
+// receiver to frame.rName for. The other testingTBMethods (Skip, Cleanup,
+// TempDir, Setenv, Deadline, Chdir, ...) have no r.<Method> counterpart on
+// *retry.R, so rewriting those would produce code that fails to compile.
+var fixableTestingTBMethods = map[string]bool{
+	"Error":   true,
+	"Errorf":  true,
+	"Fail":    true,
+	"FailNow": true,
+	"Fatal":   true,
+	"Fatalf":  true,
+}
+
+// HandleKind describes a test-handle-like type whose methods must not be
+// called, on a value built from the outer test, from inside a retry.Run or
+// retry.RunWith closure.
+type HandleKind struct {
+	// Name identifies the kind in diagnostic messages, e.g. "testify
+	// require/assert".
+	Name string
+	// TypeOf reports whether expr's static type is this handle kind.
+	TypeOf func(pass *analysis.Pass, expr ast.Expr) bool
+	// Methods lists the forbidden method names. Ignored if AllMethods is
+	// set.
+	Methods map[string]bool
+	// AllMethods forbids every method call on the handle, for types (like
+	// testify's Assertions or gocheck's *check.C) that are built purely to
+	// fail the outer test.
+	AllMethods bool
+}
+
+// Registry lists the test-handle kinds retrycheck looks for in addition to
+// *testing.T/testing.TB itself. Callers embedding retrycheck (for example
+// to support another assertion library) can append their own HandleKind
+// before running the analyzer.
+var Registry = []*HandleKind{
+	{
+		Name:       "testify require/assert",
+		TypeOf:     isTestifyAssertions,
+		AllMethods: true,
+	},
+	{
+		Name:    "gocheck *check.C",
+		TypeOf:  isGocheckC,
+		Methods: gocheckFailMethods,
+	},
+}
+
+// gocheckFailMethods are the gocheck *check.C methods that abort or skip
+// the outer test, as opposed to harmless methods like Log/Logf/TempDir/
+// MkDir/Succeed that don't register against it.
+var gocheckFailMethods = map[string]bool{
+	"Assert":  true,
+	"Fatal":   true,
+	"Error":   true,
+	"FailNow": true,
+	"Skip":    true,
+	"SkipNow": true,
+}
+
+// forbidsMethod reports whether k disallows calling the method named name,
+// either because it forbids every method or because name is in its
+// explicit Methods set.
+func (k *HandleKind) forbidsMethod(name string) bool {
+	return k.AllMethods || k.Methods[name]
+}
+
+// forbiddenHandleCall reports whether ce calls a forbidden method, per
+// Registry, on a receiver that is one of the registered test-handle kinds.
+func forbiddenHandleCall(pass *analysis.Pass, ce *ast.CallExpr) (recv ast.Expr, kind, method string, ok bool) {
+	sel, ok := ce.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, "", "", false
+	}
+	for _, k := range Registry {
+		if !k.forbidsMethod(sel.Sel.Name) {
+			continue
+		}
+		if !k.TypeOf(pass, sel.X) {
+			continue
+		}
+		return sel.X, k.Name, sel.Sel.Name, true
+	}
+	return nil, "", "", false
+}
+
+// isTestifyAssertions reports whether expr's static type is
+// *require.Assertions or *assert.Assertions, the receiver returned by
+// require.New(t)/assert.New(t).
+func isTestifyAssertions(pass *analysis.Pass, expr ast.Expr) bool {
+	return isNamedPointerType(pass, expr,
+		"github.com/stretchr/testify/require", "Assertions",
+		"github.com/stretchr/testify/assert", "Assertions",
+	)
+}
+
+// isGocheckC reports whether expr's static type is *check.C from
+// gopkg.in/check.v1, gocheck's equivalent of *testing.T.
+func isGocheckC(pass *analysis.Pass, expr ast.Expr) bool {
+	return isNamedPointerType(pass, expr, "gopkg.in/check.v1", "C")
+}
+
+// isNamedPointerType reports whether expr's static type is a pointer to
+// one of the given (importPath, typeName) pairs.
+func isNamedPointerType(pass *analysis.Pass, expr ast.Expr, pathsAndNames ...string) bool {
+	t := pass.TypesInfo.TypeOf(expr)
+	if t == nil {
+		return false
+	}
+	ptr, ok := t.(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	if obj == nil || obj.Pkg() == nil {
+		return false
+	}
+	for i := 0; i+1 < len(pathsAndNames); i += 2 {
+		if obj.Pkg().Path() == pathsAndNames[i] && obj.Name() == pathsAndNames[i+1] {
+			return true
+		}
+	}
+	return false
+}
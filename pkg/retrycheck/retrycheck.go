@@ -0,0 +1,472 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package retrycheck defines an Analyzer that flags use of the outer
+// *testing.T inside a retry.Run or retry.RunWith closure, where the
+// closure's *retry.R argument should be used instead.
+package retrycheck
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const doc = `report use of *testing.T inside retry.Run closures
+
+Calling a testing.TB method that aborts, skips or registers against the
+outer test (Fatal, Fatalf, Error, Errorf, Fail, FailNow, Skip, Skipf,
+SkipNow, Cleanup, TempDir, Setenv, Deadline, Chdir), a testify
+require/assert helper built from the outer *testing.T, a saved
+require.New(t)/assert.New(t) receiver, or a gocheck *check.C built from
+the outer test, from inside a retry.Run or retry.RunWith closure defeats
+the retry instead of letting it retry. The closure's *retry.R argument
+must be used instead. Additional test-handle types can be registered by
+appending to retrycheck.Registry. Goroutines and deferred closures
+nested directly inside the retry closure are covered automatically; pass
+-deep to also follow calls to helper functions declared in the same
+package.
+
+A diagnostic can be suppressed with a "//lint:ignore retrycheck reason"
+or "//nolint:retrycheck" comment on the offending line, or by listing it
+in a .retrycheck.yaml/.retrycheck.json allowlist in the working
+directory; allowlist entries that stop matching anything are reported so
+the allowlist doesn't rot.
+
+Run with -fix to rewrite Fatal, Fatalf, Error, Errorf, Fail and FailNow
+calls on the outer test, and require/assert calls that take it, to use
+the closure's *retry.R argument. The other diagnostics have no
+automatic fix.`
+
+// maxCallDepth bounds how many helper-call hops -deep will follow from a
+// retry.Run/RunWith closure, so that traversal stays cheap on large trees.
+const maxCallDepth = 5
+
+func newFlagSet() flag.FlagSet {
+	fs := flag.NewFlagSet("retrycheck", flag.ExitOnError)
+	fs.Bool("deep", false, "follow calls to same-package helper functions for a *testing.T/testing.TB escaping a retry.Run closure")
+	return *fs
+}
+
+// Analyzer reports use of the outer *testing.T inside a retry.Run or
+// retry.RunWith closure, where the closure's *retry.R should be used
+// instead.
+var Analyzer = &analysis.Analyzer{
+	Name:     "retrycheck",
+	Doc:      doc,
+	Flags:    newFlagSet(),
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+const retryPath = `"github.com/hashicorp/consul/sdk/testutil/retry"`
+
+const testingPath = "testing"
+
+// retryFrame tracks the *retry.R identifier in scope for the retry.Run (or
+// RunWith) closure that encloses the node currently being visited, and the
+// AST stack depth at which that closure was entered.
+type retryFrame struct {
+	depth int
+	rName string
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if !importsPackage(pass, retryPath) {
+		return nil, nil
+	}
+
+	tb := testingTB(pass)
+	if tb == nil {
+		return nil, nil
+	}
+
+	cfg, err := loadAllowlist()
+	if err != nil {
+		return nil, err
+	}
+	sup := collectSuppressions(pass)
+
+	var helperFuncs map[string]*ast.FuncDecl
+	var kinds []*HandleKind
+	if pass.Analyzer.Flags.Lookup("deep").Value.String() == "true" {
+		helperFuncs = collectFuncDecls(pass)
+		kinds = handleKinds(tb)
+	}
+
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{(*ast.CallExpr)(nil), (*ast.FuncDecl)(nil)}
+
+	var frame retryFrame
+	var fn funcFrame
+	insp.WithStack(nodeFilter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			if frame.depth > 0 && len(stack) <= frame.depth {
+				frame = retryFrame{}
+			}
+			if fn.depth > 0 && len(stack) <= fn.depth {
+				fn = funcFrame{}
+			}
+			return true
+		}
+
+		if fd, ok := n.(*ast.FuncDecl); ok {
+			fn = funcFrame{depth: len(stack), name: fd.Name.Name}
+			return true
+		}
+
+		ce := n.(*ast.CallExpr)
+
+		emit := func(diag analysis.Diagnostic) {
+			if sup.suppressed(pass, diag.Pos) || cfg.matches(pass, diag.Pos, fn.name) {
+				return
+			}
+			pass.Report(diag)
+		}
+
+		if rName, ok := retryClosureParam(ce); ok {
+			frame = retryFrame{depth: len(stack), rName: rName}
+			return true
+		}
+
+		if frame.depth == 0 {
+			return true
+		}
+
+		if recv, name, ok := testingTFailerCall(pass, tb, ce); ok {
+			diag := analysis.Diagnostic{
+				Pos:     ce.Pos(),
+				Message: fmt.Sprintf("%s.%s called inside retry.Run; use %s.%s instead", exprString(recv), name, frame.rName, name),
+			}
+			if id, ok := recv.(*ast.Ident); ok && fixableTestingTBMethods[name] {
+				diag.SuggestedFixes = []analysis.SuggestedFix{{
+					Message:   fmt.Sprintf("replace %q with %q", id.Name, frame.rName),
+					TextEdits: []analysis.TextEdit{replaceIdent(id, frame.rName)},
+				}}
+			}
+			emit(diag)
+			return true
+		}
+
+		if recv, kind, method, ok := forbiddenHandleCall(pass, ce); ok {
+			// A handle declared inside the retry call (e.g. require.New(r))
+			// was not built from the outer test.
+			retryCall := stack[frame.depth-1]
+			if id, ok := recv.(*ast.Ident); ok {
+				if obj := pass.TypesInfo.ObjectOf(id); obj != nil && obj.Pos() >= retryCall.Pos() && obj.Pos() < retryCall.End() {
+					return true
+				}
+			}
+			diag := analysis.Diagnostic{
+				Pos:     ce.Pos(),
+				Message: fmt.Sprintf("%s.%s called inside retry.Run; %s was built from the outer test, use %s instead", exprString(recv), method, kind, frame.rName),
+			}
+			emit(diag)
+			return true
+		}
+
+		if tArg, ok := requireCallOnT(pass, tb, ce); ok {
+			diag := analysis.Diagnostic{
+				Pos:     ce.Pos(),
+				Message: fmt.Sprintf("require/assert called with a testing.TB inside retry.Run; use %s instead", frame.rName),
+			}
+			if id, ok := tArg.(*ast.Ident); ok {
+				diag.SuggestedFixes = []analysis.SuggestedFix{{
+					Message:   fmt.Sprintf("replace %q with %q", id.Name, frame.rName),
+					TextEdits: []analysis.TextEdit{replaceIdent(id, frame.rName)},
+				}}
+			}
+			emit(diag)
+			return true
+		}
+
+		if helperFuncs != nil {
+			checkHelperEscape(pass, kinds, helperFuncs, ce, frame.rName, maxCallDepth, cfg, sup)
+		}
+
+		return true
+	})
+
+	reportStaleRules(pass, cfg)
+	return nil, nil
+}
+
+// funcFrame tracks the name of the function declaration enclosing the node
+// currently being visited, and the AST stack depth at which it was entered,
+// so allowlist rules can match by enclosing function name.
+type funcFrame struct {
+	depth int
+	name  string
+}
+
+// importsPackage reports whether the package under analysis directly
+// imports importPath.
+func importsPackage(pass *analysis.Pass, importPath string) bool {
+	for _, f := range pass.Files {
+		for _, imp := range f.Imports {
+			if imp.Path.Value == importPath {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// testingTB returns the standard library testing.TB interface type, as seen
+// by the package under analysis, or nil if that package doesn't import
+// "testing".
+func testingTB(pass *analysis.Pass) types.Type {
+	for _, imp := range pass.Pkg.Imports() {
+		if imp.Path() != testingPath {
+			continue
+		}
+		obj := imp.Scope().Lookup("TB")
+		if obj == nil {
+			return nil
+		}
+		return obj.Type()
+	}
+	return nil
+}
+
+// isTestingTB reports whether expr's static type is assignable to
+// testing.TB, which *testing.T, *testing.B and *testing.F all implement.
+// Unlike a syntactic check on the identifier name "t", this follows
+// aliases, dot imports, and values threaded through helper parameters.
+func isTestingTB(pass *analysis.Pass, tb types.Type, expr ast.Expr) bool {
+	t := pass.TypesInfo.TypeOf(expr)
+	if t == nil {
+		return false
+	}
+	return types.AssignableTo(t, tb)
+}
+
+// retryClosureParam reports whether ce is a call to retry.Run(t, func(r
+// *retry.R){...}) or retry.RunWith(failer, t, func(r *retry.R){...}), and if
+// so returns the name the closure gives its *retry.R parameter.
+func retryClosureParam(ce *ast.CallExpr) (string, bool) {
+	sel, ok := ce.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok || pkg.Name != "retry" {
+		return "", false
+	}
+
+	var lit *ast.FuncLit
+	switch sel.Sel.Name {
+	case "Run":
+		if len(ce.Args) != 2 {
+			return "", false
+		}
+		lit, ok = ce.Args[1].(*ast.FuncLit)
+	case "RunWith":
+		if len(ce.Args) != 3 {
+			return "", false
+		}
+		lit, ok = ce.Args[2].(*ast.FuncLit)
+	default:
+		return "", false
+	}
+	if !ok || lit.Type.Params == nil || len(lit.Type.Params.List) != 1 {
+		return "", false
+	}
+
+	param := lit.Type.Params.List[0]
+	if len(param.Names) != 1 {
+		return "", false
+	}
+	return param.Names[0].Name, true
+}
+
+// testingTFailerCall reports whether ce invokes one of the testingTBMethods
+// on a receiver whose type is assignable to testing.TB,
+// e.g. t.Fatal(...) or someTB.Errorf(...).
+func testingTFailerCall(pass *analysis.Pass, tb types.Type, ce *ast.CallExpr) (recv ast.Expr, name string, ok bool) {
+	sel, ok := ce.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, "", false
+	}
+	if !testingTBMethods[sel.Sel.Name] || !isTestingTB(pass, tb, sel.X) {
+		return nil, "", false
+	}
+	return sel.X, sel.Sel.Name, true
+}
+
+// requireCallOnT reports whether ce is a call into the testify require or
+// assert packages whose first argument's type is assignable to
+// testing.TB, e.g. require.NoError(t, err) or require.New(t).
+func requireCallOnT(pass *analysis.Pass, tb types.Type, ce *ast.CallExpr) (ast.Expr, bool) {
+	sel, ok := ce.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok || (pkg.Name != "require" && pkg.Name != "assert") {
+		return nil, false
+	}
+	if len(ce.Args) == 0 {
+		return nil, false
+	}
+	arg := ce.Args[0]
+	if !isTestingTB(pass, tb, arg) {
+		return nil, false
+	}
+	return arg, true
+}
+
+func replaceIdent(id *ast.Ident, newName string) analysis.TextEdit {
+	return analysis.TextEdit{
+		Pos:     id.Pos(),
+		End:     id.End(),
+		NewText: []byte(newName),
+	}
+}
+
+// exprString renders expr for diagnostic messages; it only needs to handle
+// the shapes that can appear as a testing.TB-typed receiver or argument.
+func exprString(expr ast.Expr) string {
+	if id, ok := expr.(*ast.Ident); ok {
+		return id.Name
+	}
+	return "value"
+}
+
+// collectFuncDecls indexes the plain (non-method) functions declared in the
+// package under analysis by name, for -deep's intra-package call following.
+func collectFuncDecls(pass *analysis.Pass) map[string]*ast.FuncDecl {
+	funcs := make(map[string]*ast.FuncDecl)
+	for _, f := range pass.Files {
+		for _, decl := range f.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Recv != nil || fd.Body == nil {
+				continue
+			}
+			funcs[fd.Name.Name] = fd
+		}
+	}
+	return funcs
+}
+
+// handleKinds returns the test-handle kinds -deep should follow into helper
+// calls: testing.TB itself (wrapped as a HandleKind so it shares the same
+// tracking and method-lookup machinery), plus every kind in Registry, so a
+// require.Assertions/assert.Assertions/gocheck *check.C value threaded
+// through a helper parameter is followed the same way a *testing.T/TB one
+// is.
+func handleKinds(tb types.Type) []*HandleKind {
+	kinds := make([]*HandleKind, 0, len(Registry)+1)
+	kinds = append(kinds, &HandleKind{
+		Name:    "testing.TB",
+		TypeOf:  func(pass *analysis.Pass, expr ast.Expr) bool { return isTestingTB(pass, tb, expr) },
+		Methods: testingTBMethods,
+	})
+	return append(kinds, Registry...)
+}
+
+// checkHelperEscape follows a call to a same-package helper function made
+// from inside a retry.Run/RunWith closure, and reports any forbidden method
+// (per kinds) reachable from it, bounded by budget call hops.
+func checkHelperEscape(pass *analysis.Pass, kinds []*HandleKind, funcs map[string]*ast.FuncDecl, ce *ast.CallExpr, rName string, budget int, cfg *allowlistConfig, sup lineSuppressions) {
+	if budget <= 0 {
+		return
+	}
+	id, ok := ce.Fun.(*ast.Ident)
+	if !ok {
+		return
+	}
+	decl, ok := funcs[id.Name]
+	if !ok {
+		return
+	}
+
+	tracked := trackedArgKinds(pass, kinds, decl.Type.Params, ce.Args)
+	if len(tracked) == 0 {
+		return
+	}
+
+	reportEscapes(pass, kinds, funcs, decl.Body, tracked, id.Name, rName, budget-1, cfg, sup)
+}
+
+// trackedArgKinds returns, for each param whose corresponding argument at
+// the call site matches one of kinds (testing.TB or a registered
+// HandleKind), the param name mapped to the kind that argument matched,
+// i.e. the parameters a test-handle value passed at ce actually flows
+// into.
+func trackedArgKinds(pass *analysis.Pass, kinds []*HandleKind, params *ast.FieldList, args []ast.Expr) map[string]*HandleKind {
+	tracked := make(map[string]*HandleKind)
+	if params == nil {
+		return tracked
+	}
+
+	var names []string
+	for _, field := range params.List {
+		if len(field.Names) == 0 {
+			names = append(names, "")
+			continue
+		}
+		for _, n := range field.Names {
+			names = append(names, n.Name)
+		}
+	}
+
+	for i, arg := range args {
+		if i >= len(names) || names[i] == "" {
+			continue
+		}
+		for _, k := range kinds {
+			if k.TypeOf(pass, arg) {
+				tracked[names[i]] = k
+				break
+			}
+		}
+	}
+	return tracked
+}
+
+// reportEscapes walks body reporting any forbidden method (per the
+// HandleKind recorded in tracked) called on a tracked identifier, and
+// recurses (bounded by budget) into further same-package helper calls and
+// aliasing assignments.
+func reportEscapes(pass *analysis.Pass, kinds []*HandleKind, funcs map[string]*ast.FuncDecl, body ast.Node, tracked map[string]*HandleKind, helperName, rName string, budget int, cfg *allowlistConfig, sup lineSuppressions) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			for i, rhs := range node.Rhs {
+				rid, ok := rhs.(*ast.Ident)
+				if !ok || tracked[rid.Name] == nil || i >= len(node.Lhs) {
+					continue
+				}
+				if lid, ok := node.Lhs[i].(*ast.Ident); ok {
+					tracked[lid.Name] = tracked[rid.Name]
+				}
+			}
+		case *ast.CallExpr:
+			if sel, ok := node.Fun.(*ast.SelectorExpr); ok {
+				if recv, ok := sel.X.(*ast.Ident); ok {
+					if k := tracked[recv.Name]; k != nil && k.forbidsMethod(sel.Sel.Name) {
+						if !sup.suppressed(pass, node.Pos()) && !cfg.matches(pass, node.Pos(), helperName) {
+							pass.Report(analysis.Diagnostic{
+								Pos: node.Pos(),
+								Message: fmt.Sprintf(
+									"%s.%s reachable from retry.Run through %s; pass %s instead of %s to %s",
+									recv.Name, sel.Sel.Name, helperName, rName, recv.Name, helperName,
+								),
+							})
+						}
+					}
+				}
+			}
+			if budget > 0 {
+				checkHelperEscape(pass, kinds, funcs, node, rName, budget, cfg, sup)
+			}
+		}
+		return true
+	})
+}
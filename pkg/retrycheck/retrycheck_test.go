@@ -0,0 +1,51 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package retrycheck_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/hashicorp/lint-consul-retry/pkg/retrycheck"
+)
+
+func TestAnalyzer(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, retrycheck.Analyzer, "a")
+}
+
+func TestAnalyzerFix(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, retrycheck.Analyzer, "a")
+}
+
+func TestAnalyzerDeep(t *testing.T) {
+	if err := retrycheck.Analyzer.Flags.Set("deep", "true"); err != nil {
+		t.Fatalf("failed to enable -deep: %v", err)
+	}
+	defer retrycheck.Analyzer.Flags.Set("deep", "false")
+
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, retrycheck.Analyzer, "deeppkg")
+}
+
+func TestAnalyzerSuppressed(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, retrycheck.Analyzer, "suppressed")
+}
+
+func TestAnalyzerBroad(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, retrycheck.Analyzer, "broad")
+}
+
+// TestAnalyzerBroadFix guards against -fix rewriting a method that has no
+// *retry.R equivalent (e.g. t.Skip, t.Cleanup, t.Setenv): the golden file
+// is identical to the source, so any suggested fix for those diagnostics
+// would make this test fail.
+func TestAnalyzerBroadFix(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, retrycheck.Analyzer, "broad")
+}
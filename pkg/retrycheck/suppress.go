@@ -0,0 +1,184 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package retrycheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/tools/go/analysis"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	allowlistYAMLName = ".retrycheck.yaml"
+	allowlistJSONName = ".retrycheck.json"
+)
+
+// ignoreRule is one entry of a .retrycheck.yaml/.retrycheck.json allowlist,
+// identifying a diagnostic to suppress by file and, optionally, enclosing
+// function name and/or line number. An empty field matches anything.
+type ignoreRule struct {
+	File string `json:"file" yaml:"file"`
+	Func string `json:"func" yaml:"func"`
+	Line int    `json:"line" yaml:"line"`
+
+	// matched records whether this rule has suppressed a diagnostic.
+	// allowlist is cached and shared across every pass's Run, and
+	// go/analysis drivers run passes for independent packages
+	// concurrently, so this is written and read from multiple goroutines.
+	matched atomic.Bool
+}
+
+type allowlistConfig struct {
+	Ignore []*ignoreRule `json:"ignore" yaml:"ignore"`
+}
+
+var (
+	allowlistOnce sync.Once
+	allowlist     *allowlistConfig
+	allowlistErr  error
+)
+
+// loadAllowlist reads the .retrycheck.yaml or .retrycheck.json allowlist
+// from the current working directory. The result is cached for the life of
+// the process since every pass shares the same allowlist.
+func loadAllowlist() (*allowlistConfig, error) {
+	allowlistOnce.Do(func() {
+		dir, err := os.Getwd()
+		if err != nil {
+			allowlistErr = fmt.Errorf("failed to get working directory: %w", err)
+			return
+		}
+		allowlist, allowlistErr = readAllowlist(dir)
+	})
+	return allowlist, allowlistErr
+}
+
+func readAllowlist(dir string) (*allowlistConfig, error) {
+	for _, name := range []string{allowlistYAMLName, allowlistJSONName} {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		cfg := &allowlistConfig{}
+		if strings.HasSuffix(name, ".json") {
+			err = json.Unmarshal(data, cfg)
+		} else {
+			err = yaml.Unmarshal(data, cfg)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		return cfg, nil
+	}
+	return &allowlistConfig{}, nil
+}
+
+// matches reports whether pos, inside function funcName, is covered by an
+// allowlist rule, marking that rule as matched so it isn't later flagged as
+// stale.
+func (c *allowlistConfig) matches(pass *analysis.Pass, pos token.Pos, funcName string) bool {
+	if c == nil {
+		return false
+	}
+
+	p := pass.Fset.Position(pos)
+	for _, rule := range c.Ignore {
+		if rule.File != "" && !strings.HasSuffix(filepath.ToSlash(p.Filename), filepath.ToSlash(rule.File)) {
+			continue
+		}
+		if rule.Func != "" && rule.Func != funcName {
+			continue
+		}
+		if rule.Line != 0 && rule.Line != p.Line {
+			continue
+		}
+		rule.matched.Store(true)
+		return true
+	}
+	return false
+}
+
+// reportStaleRules flags allowlist entries whose File matches a file seen
+// in this pass but that never suppressed a diagnostic while processing it,
+// so the allowlist doesn't rot the way an unused //nolint comment would.
+func reportStaleRules(pass *analysis.Pass, c *allowlistConfig) {
+	if c == nil {
+		return
+	}
+	for _, rule := range c.Ignore {
+		if rule.matched.Load() || rule.File == "" {
+			continue
+		}
+		f := fileForRule(pass, rule.File)
+		if f == nil {
+			continue
+		}
+		pass.Reportf(f.Pos(), "retrycheck: allowlist entry for %q (func %q, line %d) did not match any diagnostic", rule.File, rule.Func, rule.Line)
+	}
+}
+
+func fileForRule(pass *analysis.Pass, ruleFile string) *ast.File {
+	for _, f := range pass.Files {
+		name := pass.Fset.Position(f.Pos()).Filename
+		if strings.HasSuffix(filepath.ToSlash(name), filepath.ToSlash(ruleFile)) {
+			return f
+		}
+	}
+	return nil
+}
+
+// lineSuppressions records, per file, the line numbers carrying a
+// "//lint:ignore retrycheck ..." or "//nolint:retrycheck" comment.
+type lineSuppressions map[string]map[int]bool
+
+func (s lineSuppressions) suppressed(pass *analysis.Pass, pos token.Pos) bool {
+	p := pass.Fset.Position(pos)
+	return s[p.Filename][p.Line]
+}
+
+// collectSuppressions scans every file in the pass for inline retrycheck
+// suppression comments, honoring them on whichever line they appear on.
+func collectSuppressions(pass *analysis.Pass) lineSuppressions {
+	sup := make(lineSuppressions)
+	for _, f := range pass.Files {
+		filename := pass.Fset.Position(f.Pos()).Filename
+		for _, cg := range f.Comments {
+			for _, c := range cg.List {
+				if !isSuppressionComment(c.Text) {
+					continue
+				}
+				if sup[filename] == nil {
+					sup[filename] = make(map[int]bool)
+				}
+				sup[filename][pass.Fset.Position(c.Pos()).Line] = true
+			}
+		}
+	}
+	return sup
+}
+
+func isSuppressionComment(text string) bool {
+	text = strings.TrimSpace(strings.TrimPrefix(text, "//"))
+	if strings.HasPrefix(text, "lint:ignore retrycheck") {
+		return true
+	}
+	if text == "nolint:retrycheck" || strings.HasPrefix(text, "nolint:retrycheck ") {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package retrycheck
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadAllowlistJSON(t *testing.T) {
+	dir := t.TempDir()
+	const body = `{"ignore": [{"file": "foo.go", "func": "TestFoo", "line": 12}]}`
+	if err := os.WriteFile(filepath.Join(dir, allowlistJSONName), []byte(body), 0o644); err != nil {
+		t.Fatalf("failed to write allowlist: %v", err)
+	}
+
+	cfg, err := readAllowlist(dir)
+	if err != nil {
+		t.Fatalf("readAllowlist() returned error: %v", err)
+	}
+	if len(cfg.Ignore) != 1 {
+		t.Fatalf("got %d ignore rules, want 1", len(cfg.Ignore))
+	}
+	if got := cfg.Ignore[0]; got.File != "foo.go" || got.Func != "TestFoo" || got.Line != 12 {
+		t.Fatalf("got rule %+v, want {File:foo.go Func:TestFoo Line:12}", got)
+	}
+}
+
+func TestReadAllowlistYAML(t *testing.T) {
+	dir := t.TempDir()
+	const body = "ignore:\n  - file: foo.go\n    func: TestFoo\n"
+	if err := os.WriteFile(filepath.Join(dir, allowlistYAMLName), []byte(body), 0o644); err != nil {
+		t.Fatalf("failed to write allowlist: %v", err)
+	}
+
+	cfg, err := readAllowlist(dir)
+	if err != nil {
+		t.Fatalf("readAllowlist() returned error: %v", err)
+	}
+	if len(cfg.Ignore) != 1 || cfg.Ignore[0].File != "foo.go" || cfg.Ignore[0].Func != "TestFoo" {
+		t.Fatalf("got %+v, want one rule for foo.go/TestFoo", cfg.Ignore)
+	}
+}
+
+func TestReadAllowlistMissing(t *testing.T) {
+	cfg, err := readAllowlist(t.TempDir())
+	if err != nil {
+		t.Fatalf("readAllowlist() returned error: %v", err)
+	}
+	if len(cfg.Ignore) != 0 {
+		t.Fatalf("got %d ignore rules, want 0 for a directory with no allowlist file", len(cfg.Ignore))
+	}
+}
+
+func TestIsSuppressionComment(t *testing.T) {
+	cases := map[string]bool{
+		"//lint:ignore retrycheck reason": true,
+		"//nolint:retrycheck reason":      true,
+		"//nolint:retrycheck":             true,
+		"//nolint":                        false,
+		"//lint:ignore unused reason":     false,
+		"// just a regular comment":       false,
+	}
+	for text, want := range cases {
+		if got := isSuppressionComment(text); got != want {
+			t.Errorf("isSuppressionComment(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
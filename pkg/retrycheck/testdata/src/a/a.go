@@ -0,0 +1,59 @@
+package a
+
+import (
+	"testing"
+
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOK(t *testing.T) {
+	retry.Run(t, func(r *retry.R) {
+		r.Fatal("ok to fail the retry")
+	})
+}
+
+func TestFatalOnT(t *testing.T) {
+	retry.Run(t, func(r *retry.R) {
+		t.Fatal("bad") // want `t.Fatal called inside retry.Run; use r.Fatal instead`
+	})
+}
+
+func TestErrorfOnT(t *testing.T) {
+	retry.RunWith(&retry.Counter{}, t, func(r *retry.R) {
+		t.Errorf("bad %d", 1) // want `t.Errorf called inside retry.Run; use r.Errorf instead`
+	})
+}
+
+func TestRequireOnT(t *testing.T) {
+	retry.Run(t, func(r *retry.R) {
+		require.NoError(t, nil) // want `require/assert called with a testing\.TB inside retry\.Run; use r instead`
+	})
+}
+
+func TestRequireNewOnT(t *testing.T) {
+	retry.Run(t, func(r *retry.R) {
+		require.New(t) // want `require/assert called with a testing\.TB inside retry\.Run; use r instead`
+	})
+}
+
+func TestNestedHelper(t *testing.T) {
+	retry.Run(t, func(r *retry.R) {
+		if true {
+			t.Fatal("bad") // want `t.Fatal called inside retry.Run; use r.Fatal instead`
+		}
+	})
+}
+
+func TestAliasedT(t *testing.T) {
+	t2 := t
+	retry.Run(t, func(r *retry.R) {
+		t2.Fatal("bad") // want `t2.Fatal called inside retry.Run; use r.Fatal instead`
+	})
+}
+
+func TestTestingTBParam(tb testing.TB) {
+	retry.Run(tb, func(r *retry.R) {
+		tb.Fatal("bad") // want `tb.Fatal called inside retry.Run; use r.Fatal instead`
+	})
+}
@@ -0,0 +1,19 @@
+package allowlisted // want `retrycheck: allowlist entry for "allowlisted\.go" \(func "Missing", line 0\) did not match any diagnostic`
+
+import (
+	"testing"
+
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+)
+
+func TestAllowlistedFatal(t *testing.T) {
+	retry.Run(t, func(r *retry.R) {
+		t.Fatal("bad") // suppressed by the .retrycheck.yaml entry for this func
+	})
+}
+
+func TestStillFlagged(t *testing.T) {
+	retry.Run(t, func(r *retry.R) {
+		t.Fatal("bad") // want `t.Fatal called inside retry.Run; use r.Fatal instead`
+	})
+}
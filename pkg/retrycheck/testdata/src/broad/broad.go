@@ -0,0 +1,48 @@
+package broad
+
+import (
+	"testing"
+
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	check "gopkg.in/check.v1"
+)
+
+func TestSkip(t *testing.T) {
+	retry.Run(t, func(r *retry.R) {
+		t.Skip("nope") // want `t\.Skip called inside retry\.Run; use r\.Skip instead`
+	})
+}
+
+func TestCleanup(t *testing.T) {
+	retry.Run(t, func(r *retry.R) {
+		t.Cleanup(func() {}) // want `t\.Cleanup called inside retry\.Run; use r\.Cleanup instead`
+	})
+}
+
+func TestSetenv(t *testing.T) {
+	retry.Run(t, func(r *retry.R) {
+		t.Setenv("FOO", "bar") // want `t\.Setenv called inside retry\.Run; use r\.Setenv instead`
+	})
+}
+
+func TestRequireReceiver(t *testing.T) {
+	req := require.New(t)
+	retry.Run(t, func(r *retry.R) {
+		req.NoError(nil) // want `req\.NoError called inside retry\.Run; testify require/assert was built from the outer test, use r instead`
+	})
+}
+
+func TestAssertReceiver(t *testing.T) {
+	a := assert.New(t)
+	retry.Run(t, func(r *retry.R) {
+		a.NoError(nil) // want `a\.NoError called inside retry\.Run; testify require/assert was built from the outer test, use r instead`
+	})
+}
+
+func helperWithCheck(t *testing.T, c *check.C) {
+	retry.Run(t, func(r *retry.R) {
+		c.Assert(1, 1) // want `c\.Assert called inside retry\.Run; gocheck \*check\.C was built from the outer test, use r instead`
+	})
+}
@@ -0,0 +1,49 @@
+package deeppkg
+
+import (
+	"testing"
+
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+	"github.com/stretchr/testify/require"
+)
+
+func helperA(t testing.TB) {
+	t.Fatal("bad") // want `t\.Fatal reachable from retry\.Run through helperA; pass r instead of t to helperA`
+}
+
+func helperB(t testing.TB) {
+	t.Fatal("bad") // want `t\.Fatal reachable from retry\.Run through helperB; pass r instead of t to helperB`
+}
+
+func indirectHelper(t testing.TB) {
+	helperB(t)
+}
+
+func TestHelperEscape(t *testing.T) {
+	retry.Run(t, func(r *retry.R) {
+		helperA(t)
+	})
+}
+
+func TestIndirectHelperEscape(t *testing.T) {
+	retry.Run(t, func(r *retry.R) {
+		indirectHelper(t)
+	})
+}
+
+func TestHelperNotCalledWithT(t *testing.T) {
+	retry.Run(t, func(r *retry.R) {
+		_ = r
+	})
+}
+
+func helperWithAssertions(req *require.Assertions) {
+	req.NoError(nil) // want `req\.NoError reachable from retry\.Run through helperWithAssertions; pass r instead of req to helperWithAssertions`
+}
+
+func TestHelperEscapeThroughAssertions(t *testing.T) {
+	req := require.New(t)
+	retry.Run(t, func(r *retry.R) {
+		helperWithAssertions(req)
+	})
+}
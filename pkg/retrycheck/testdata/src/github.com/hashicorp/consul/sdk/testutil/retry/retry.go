@@ -0,0 +1,23 @@
+// Package retry is a minimal stand-in for
+// github.com/hashicorp/consul/sdk/testutil/retry, just enough of its API
+// surface for retrycheck's testdata fixtures to type-check.
+package retry
+
+import "testing"
+
+// R is passed to the function run by Run and RunWith.
+type R struct{}
+
+func (r *R) Fatal(args ...interface{})                 {}
+func (r *R) Fatalf(format string, args ...interface{}) {}
+func (r *R) Error(args ...interface{})                 {}
+func (r *R) Errorf(format string, args ...interface{}) {}
+func (r *R) Fail()                                     {}
+func (r *R) FailNow()                                  {}
+
+// Counter is one of the Failer implementations accepted by RunWith.
+type Counter struct{}
+
+func Run(t testing.TB, f func(r *R)) {}
+
+func RunWith(failer interface{}, t testing.TB, f func(r *R)) {}
@@ -0,0 +1,12 @@
+// Package check is a minimal stand-in for gopkg.in/check.v1, just enough
+// of its API surface for retrycheck's testdata fixtures to type-check.
+package check
+
+// C is gocheck's equivalent of *testing.T.
+type C struct{}
+
+func (c *C) Assert(obtained interface{}, checker interface{}, args ...interface{}) {}
+func (c *C) Fatal(args ...interface{})                                             {}
+func (c *C) Error(args ...interface{})                                             {}
+func (c *C) FailNow()                                                              {}
+func (c *C) Skip(args ...interface{})                                              {}
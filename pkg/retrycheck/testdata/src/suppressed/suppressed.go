@@ -0,0 +1,25 @@
+package suppressed
+
+import (
+	"testing"
+
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+)
+
+func TestIgnoredViaLintComment(t *testing.T) {
+	retry.Run(t, func(r *retry.R) {
+		t.Fatal("bad") //lint:ignore retrycheck the parent test intentionally fails immediately here
+	})
+}
+
+func TestIgnoredViaNolint(t *testing.T) {
+	retry.Run(t, func(r *retry.R) {
+		t.Fatal("bad") //nolint:retrycheck helper intentionally fails the parent test
+	})
+}
+
+func TestStillFlagged(t *testing.T) {
+	retry.Run(t, func(r *retry.R) {
+		t.Fatal("bad") // want `t.Fatal called inside retry.Run; use r.Fatal instead`
+	})
+}